@@ -0,0 +1,173 @@
+package node
+
+import (
+	"encoding/binary"
+	"erlang/dist"
+	"erlang/epmd"
+	"erlang/term"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SEND is the distribution control message opcode for a plain (non-reg)
+// send to a pid, as used by sendRemote in node.go.
+const SEND = 2
+
+// nodeConn holds the live connection to a neighbor node plus the channel
+// its writer goroutine drains, so Send can hand off outbound terms without
+// blocking on the socket.
+type nodeConn struct {
+	conn net.Conn
+	send chan []term.Term
+}
+
+// neighborTable guards the neighbors map: Connect writes it from whatever
+// goroutine calls it, while every process's Send/sendRemote reads it
+// concurrently, so plain map access would race.
+type neighborTable struct {
+	mu    sync.RWMutex
+	conns map[term.Atom]nodeConn
+}
+
+func newNeighborTable() *neighborTable {
+	return &neighborTable{conns: make(map[term.Atom]nodeConn)}
+}
+
+func (t *neighborTable) load(node term.Atom) (nc nodeConn, ok bool) {
+	t.mu.RLock()
+	nc, ok = t.conns[node]
+	t.mu.RUnlock()
+	return
+}
+
+func (t *neighborTable) store(node term.Atom, nc nodeConn) {
+	t.mu.Lock()
+	t.conns[node] = nc
+	t.mu.Unlock()
+}
+
+// Connect sets up an outbound connection to remote, resolving its
+// distribution port via EPMD and running the client side of the Erlang
+// distribution handshake. Once connected, Send to pids living on remote is
+// routed over the resulting connection; RegSend and SendFrom deliver only to
+// processes on this node.
+func (n *Node) Connect(remote string) (err error) {
+	if _, ok := n.neighbors.load(term.Atom(remote)); ok {
+		return nil
+	}
+
+	ns := strings.SplitN(remote, "@", 2)
+	if len(ns) != 2 {
+		return fmt.Errorf("malformed node name '%s'", remote)
+	}
+
+	port, err := n.resolvePort(ns[0], ns[1])
+	if err != nil {
+		return err
+	}
+
+	c, err := n.transport.Dial("tcp", n.dialAddr(remote, port))
+	if err != nil {
+		return err
+	}
+
+	nd := dist.NewNodeDesc(n.FullName, n.Cookie, true)
+	if err = nd.SendName(c); err != nil {
+		c.Close()
+		return err
+	}
+	if err = nd.RecvStatus(c); err != nil {
+		c.Close()
+		return err
+	}
+	challenge, err := nd.RecvChallenge(c)
+	if err != nil {
+		c.Close()
+		return err
+	}
+	if err = nd.SendChallengeReply(c, challenge); err != nil {
+		c.Close()
+		return err
+	}
+	if err = nd.RecvChallengeAck(c); err != nil {
+		c.Close()
+		return err
+	}
+
+	nc := nodeConn{
+		conn: c,
+		send: make(chan []term.Term, 100),
+	}
+	n.neighbors.store(term.Atom(remote), nc)
+
+	go n.neighborWriter(nd, nc)
+	go n.readLoop(c, nd)
+
+	return nil
+}
+
+// dialAddr is the address Connect dials to reach remote. Under pipeTransport
+// there is no real port to dial, so it must match the full node name
+// listenAddr registered with Listen.
+func (n *Node) dialAddr(remote string, port uint16) string {
+	if _, ok := n.transport.(pipeTransport); ok {
+		return remote
+	}
+	ns := strings.SplitN(remote, "@", 2)
+	return net.JoinHostPort(ns[1], strconv.Itoa(int(port)))
+}
+
+// resolvePort asks the EPMD running on host for the distribution port of
+// name, via PORT_PLEASE2_REQ. Under pipeTransport there is no real EPMD, so
+// it looks the port up in the in-process pipeEPMD registry instead.
+func (n *Node) resolvePort(name, host string) (port uint16, err error) {
+	if _, ok := n.transport.(pipeTransport); ok {
+		port, ok := pipeEPMDLookup(name + "@" + host)
+		if !ok {
+			return 0, fmt.Errorf("node '%s@%s' not published with pipeEPMD", name, host)
+		}
+		return port, nil
+	}
+
+	c, err := n.transport.Dial("tcp", net.JoinHostPort(host, "4369"))
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	req := epmd.Compose_PORT_PLEASE2_REQ(name)
+	buf := make([]byte, 2+len(req))
+	binary.BigEndian.PutUint16(buf[0:2], uint16(len(req)))
+	copy(buf[2:], req)
+	if _, err = c.Write(buf); err != nil {
+		return 0, err
+	}
+
+	reply := make([]byte, 1024)
+	nRead, err := c.Read(reply)
+	if err != nil {
+		return 0, err
+	}
+	reply = reply[:nRead]
+
+	if epmd.MessageId(reply[0]) != epmd.PORT2_RESP {
+		return 0, fmt.Errorf("unexpected EPMD reply for '%s'", name)
+	}
+	port, ok := epmd.Read_PORT2_RESP(reply)
+	if !ok {
+		return 0, fmt.Errorf("node '%s' not published with EPMD", name)
+	}
+	return port, nil
+}
+
+func (n *Node) neighborWriter(nd *dist.NodeDesc, nc nodeConn) {
+	for terms := range nc.send {
+		if err := nd.WriteMessage(nc.conn, terms); err != nil {
+			nLog("neighborWriter: %s", err.Error())
+			return
+		}
+	}
+}
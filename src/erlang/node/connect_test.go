@@ -0,0 +1,71 @@
+package node
+
+import (
+	"erlang/term"
+	"testing"
+	"time"
+)
+
+// relayWorker replies "pong" to whatever pid sent it a message, so tests can
+// confirm a reply actually makes it back across a connection.
+type relayWorker struct{}
+
+func (w *relayWorker) Behaviour() (behaviour Behaviour, options map[string]interface{}) {
+	return w, nil
+}
+
+func (w *relayWorker) ProcessLoop(pid term.Pid, pcs procChannels, pd Process, args ...interface{}) {
+	var node *Node
+	if len(args) > 0 {
+		node, _ = args[0].(*Node)
+	}
+	for msg := range pcs.inFrom {
+		if node == nil {
+			continue
+		}
+		fromPid, _ := msg.Element(1).(term.Pid)
+		node.Send(fromPid, term.Atom("pong"))
+	}
+}
+
+// TestConnectHandshakeAndBidirectionalSend drives a real Connect against a
+// real Publish accept loop over pipeTransport, then exchanges messages both
+// directions: a REG_SEND from the dialer to a process registered on the
+// acceptor, and a reply Send from the acceptor back to the dialer. The reply
+// leg only works because handleTerms' learnNeighbor registers the accepted
+// connection as a neighbor; before that fix the acceptor had no route back.
+func TestConnectHandshakeAndBidirectionalSend(t *testing.T) {
+	transport := NewPipeTransport()
+
+	acceptor := NewNode("acceptor1@localhost", "cookie", WithTransport(transport))
+	if err := acceptor.Publish(0); err != nil {
+		t.Fatalf("acceptor Publish: %v", err)
+	}
+	relayPid := acceptor.Spawn(new(relayWorker), acceptor)
+	acceptor.Register(term.Atom("relay"), relayPid)
+
+	dialer := NewNode("dialer1@localhost", "cookie", WithTransport(transport))
+	if err := dialer.Connect("acceptor1@localhost"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	pongs := make(chan term.Term, 1)
+	callerPid := dialer.procs.allocatePid()
+	dialer.procs.store(callerPid, procChannels{in: pongs})
+
+	nc, ok := dialer.neighbors.load(term.Atom("acceptor1@localhost"))
+	if !ok {
+		t.Fatal("dialer has no neighbor entry for acceptor1@localhost after Connect")
+	}
+	ctl := term.Tuple{term.Int(REG_SEND), callerPid, term.Atom(""), term.Atom("relay")}
+	nc.send <- []term.Term{ctl, term.Atom("ping")}
+
+	select {
+	case reply := <-pongs:
+		if reply != term.Term(term.Atom("pong")) {
+			t.Fatalf("expected 'pong' reply, got %#v", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the acceptor's reply to route back to the dialer")
+	}
+}
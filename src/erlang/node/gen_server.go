@@ -0,0 +1,106 @@
+package node
+
+import (
+	"erlang/term"
+)
+
+// GenServerBehaviour is the set of callbacks a user process implements in
+// order to be driven by the GenServer Behaviour, modeled after Erlang's
+// gen_server callback module.
+type GenServerBehaviour interface {
+	Init(args ...interface{})
+	HandleCall(message *term.Term, from *term.Tuple) (reply *term.Term)
+	HandleCast(message *term.Term) (result string)
+	HandleInfo(message *term.Term) (result string)
+	Terminate(reason string)
+}
+
+// GenServer is a Behaviour implementing the gen_server dispatch loop: it
+// decodes '$gen_call'/'$gen_cast' envelopes and invokes the matching
+// HandleCall/HandleCast/HandleInfo callback on pd.
+type GenServer struct {
+}
+
+func (gs *GenServer) ProcessLoop(pid term.Pid, pcs procChannels, pd Process, args ...interface{}) {
+	gsb, ok := pd.(GenServerBehaviour)
+	if !ok {
+		nLog("GenServer: %#v does not implement GenServerBehaviour", pd)
+		return
+	}
+
+	var node *Node
+	if len(args) > 0 {
+		node, _ = args[0].(*Node)
+	}
+
+	gsb.Init(args...)
+
+	for {
+		select {
+		case raw := <-pcs.in:
+			if gs.handleRaw(node, gsb, raw) {
+				return
+			}
+
+		case fromRaw := <-pcs.inFrom:
+			if gs.handleRaw(node, gsb, fromRaw.Element(2)) {
+				return
+			}
+
+		case m := <-pcs.ctl:
+			switch m {
+			case term.Term(term.Atom("$terminate")):
+				gsb.Terminate("normal")
+				return
+			}
+		}
+	}
+}
+
+// handleRaw dispatches one inbound message to the matching callback and
+// reports whether it drove the process to termination, so ProcessLoop knows
+// to stop looping instead of continuing to service a "stopped" process.
+func (gs *GenServer) handleRaw(node *Node, gsb GenServerBehaviour, raw term.Term) (stopped bool) {
+	switch t := raw.(type) {
+	case term.Tuple:
+		if len(t) == 0 {
+			return false
+		}
+		switch tag := t.Element(1).(type) {
+		case term.Atom:
+			switch tag {
+			case term.Atom("$gen_call"):
+				fromTuple, _ := t.Element(2).(term.Tuple)
+				message := t.Element(3)
+				reply := gsb.HandleCall(&message, &fromTuple)
+				if reply != nil && node != nil {
+					fromPid, _ := fromTuple.Element(1).(term.Pid)
+					ref := fromTuple.Element(2)
+					node.Send(fromPid, term.Tuple{ref, *reply})
+				}
+				return false
+			case term.Atom("$gen_cast"):
+				message := t.Element(2)
+				return gs.handleResult(gsb, gsb.HandleCast(&message))
+			default:
+				return gs.handleResult(gsb, gsb.HandleInfo(&raw))
+			}
+		default:
+			return gs.handleResult(gsb, gsb.HandleInfo(&raw))
+		}
+	default:
+		return gs.handleResult(gsb, gsb.HandleInfo(&raw))
+	}
+}
+
+// handleResult runs Terminate when result calls for it and reports whether
+// it did, so the caller can stop its dispatch loop rather than keep serving
+// a process that has already been told it's stopping.
+func (gs *GenServer) handleResult(gsb GenServerBehaviour, result string) (stopped bool) {
+	switch result {
+	case "stop", "normal":
+		gsb.Terminate(result)
+		return true
+	}
+	return false
+}
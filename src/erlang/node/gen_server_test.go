@@ -0,0 +1,114 @@
+package node
+
+import (
+	"erlang/term"
+	"testing"
+	"time"
+)
+
+type echoServer struct {
+	terminated chan string
+	castResult string // HandleCast's return value; defaults to "noreply"
+}
+
+func (s *echoServer) Behaviour() (behaviour Behaviour, options map[string]interface{}) {
+	return new(GenServer), nil
+}
+
+func (s *echoServer) Init(args ...interface{}) {}
+
+func (s *echoServer) HandleCall(message *term.Term, from *term.Tuple) (reply *term.Term) {
+	r := *message
+	return &r
+}
+
+func (s *echoServer) HandleCast(message *term.Term) (result string) {
+	if s.castResult != "" {
+		return s.castResult
+	}
+	return "noreply"
+}
+func (s *echoServer) HandleInfo(message *term.Term) (result string) { return "noreply" }
+
+func (s *echoServer) Terminate(reason string) {
+	s.terminated <- reason
+}
+
+func TestGenServerHandleCallRepliesToCaller(t *testing.T) {
+	node := NewNode("gs1@localhost", "cookie")
+	pid := node.Spawn(&echoServer{terminated: make(chan string, 1)}, node)
+
+	caller := node.procs.allocatePid()
+	replies := make(chan term.Term, 1)
+	node.procs.store(caller, procChannels{in: replies})
+
+	ref := term.Atom("ref-1")
+	node.Send(pid, term.Tuple{term.Atom("$gen_call"), term.Tuple{caller, ref}, term.Atom("hello")})
+
+	select {
+	case got := <-replies:
+		reply, ok := got.(term.Tuple)
+		if !ok || len(reply) != 2 {
+			t.Fatalf("expected a 2-tuple reply, got %#v", got)
+		}
+		if reply.Element(1) != term.Term(ref) {
+			t.Fatalf("expected reply ref %#v, got %#v", ref, reply.Element(1))
+		}
+		if reply.Element(2) != term.Term(term.Atom("hello")) {
+			t.Fatalf("expected echoed message, got %#v", reply.Element(2))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for gen_server call reply")
+	}
+}
+
+func TestGenServerStopsOnCastStop(t *testing.T) {
+	node := NewNode("gs3@localhost", "cookie")
+	srv := &echoServer{terminated: make(chan string, 1), castResult: "stop"}
+	pid := node.Spawn(srv, node)
+
+	// Look the process up before stopping it: Spawn drops it from the
+	// registry as soon as its ProcessLoop returns.
+	pcs, ok := node.procs.load(pid)
+	if !ok {
+		t.Fatal("spawned process not found in registry")
+	}
+
+	node.Send(pid, term.Tuple{term.Atom("$gen_cast"), term.Atom("bye")})
+
+	select {
+	case reason := <-srv.terminated:
+		if reason != "stop" {
+			t.Fatalf("expected Terminate(\"stop\"), got %q", reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Terminate after cast-driven stop")
+	}
+
+	select {
+	case <-pcs.exit:
+	case <-time.After(time.Second):
+		t.Fatal("ProcessLoop never returned after cast-driven stop")
+	}
+}
+
+func TestGenServerStopsOnCtlTerminate(t *testing.T) {
+	node := NewNode("gs2@localhost", "cookie")
+	srv := &echoServer{terminated: make(chan string, 1)}
+	pid := node.Spawn(srv, node)
+
+	pcs, ok := node.procs.load(pid)
+	if !ok {
+		t.Fatal("spawned process not found in registry")
+	}
+	pcs.ctl <- term.Term(term.Atom("$terminate"))
+
+	select {
+	case reason := <-srv.terminated:
+		if reason != "normal" {
+			t.Fatalf("expected Terminate(\"normal\"), got %q", reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Terminate to run after ctl shutdown")
+	}
+}
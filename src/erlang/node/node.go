@@ -27,16 +27,19 @@ func nLog(f string, a ...interface{}) {
 
 type Node struct {
 	epmd.NodeInfo
-	Cookie     string
-	port       int32
-	channels   map[term.Pid]procChannels
-	registered map[term.Atom]term.Pid
+	Cookie    string
+	port      int32
+	procs     *processRegistry
+	neighbors *neighborTable
+	rpc       *rpcTable
+	transport Transport
 }
 
 type procChannels struct {
 	in     chan term.Term
 	inFrom chan term.Tuple
 	ctl    chan term.Term
+	exit   chan term.Tuple // {Pid, Reason}, fed once the process's ProcessLoop returns or panics
 }
 
 type Behaviour interface {
@@ -47,7 +50,7 @@ type Process interface {
 	Behaviour() (behaviour Behaviour, options map[string]interface{})
 }
 
-func NewNode(name string, cookie string) (node *Node) {
+func NewNode(name string, cookie string, options ...NodeOption) (node *Node) {
 	nLog("Start with name '%s' and cookie '%s'", name, cookie)
 	// TODO: add fqdn support
 	ns := strings.Split(name, "@")
@@ -64,10 +67,15 @@ func NewNode(name string, cookie string) (node *Node) {
 	}
 
 	node = &Node{
-		NodeInfo:   nodeInfo,
-		Cookie:     cookie,
-		channels:   make(map[term.Pid]procChannels),
-		registered: make(map[term.Atom]term.Pid),
+		NodeInfo:  nodeInfo,
+		Cookie:    cookie,
+		procs:     newProcessRegistry(term.Atom(name), 0),
+		neighbors: newNeighborTable(),
+		rpc:       newRPCTable(),
+		transport: tcpTransport{},
+	}
+	for _, opt := range options {
+		opt(node)
 	}
 	return node
 }
@@ -80,6 +88,9 @@ func (n *Node) prepareProcesses() {
 	gns := new(globalNameServer)
 	gnsPid := n.Spawn(gns, n)
 	n.Register(term.Atom("global_name_server"), gnsPid)
+
+	rexPid := n.Spawn(new(rex), n)
+	n.Register(term.Atom("rex"), rexPid)
 }
 
 func (n *Node) Spawn(pd Process, args ...interface{}) (pid term.Pid) {
@@ -95,73 +106,50 @@ func (n *Node) Spawn(pd Process, args ...interface{}) (pid term.Pid) {
 	in := make(chan term.Term, chanSize)
 	inFrom := make(chan term.Tuple, chanSize)
 	ctl := make(chan term.Term, ctlChanSize)
+	exit := make(chan term.Tuple, 1)
 	pcs := procChannels{
 		in:     in,
 		inFrom: inFrom,
 		ctl:    ctl,
+		exit:   exit,
 	}
-	pid = n.storeProcess(pcs)
-	go behaviour.ProcessLoop(pid, pcs, pd, args...)
+	pid = n.procs.allocatePid()
+	n.procs.store(pid, pcs)
+	go func() {
+		reason := term.Atom("normal")
+		defer func() {
+			if r := recover(); r != nil {
+				reason = term.Atom(fmt.Sprintf("%v", r))
+			}
+			n.procs.remove(pid)
+			pcs.exit <- term.Tuple{pid, reason}
+		}()
+		behaviour.ProcessLoop(pid, pcs, pd, args...)
+	}()
 	return
 }
 
 func (n *Node) Register(name term.Atom, pid term.Pid) {
-	n.registered[name] = pid
+	n.procs.register(name, pid)
 }
 
 func (n *Node) Registered() (pids []term.Atom) {
-	pids = make([]term.Atom, len(n.registered))
-	i := 0
-	for p, _ := range n.registered {
-		pids[i] = p
-		i++
-	}
-	return
-}
-
-func (n *Node) storeProcess(chs procChannels) (pid term.Pid) {
-	pid = n.allocatePid()
-	n.channels[pid] = chs
-	return pid
-}
-
-func (n *Node) allocatePid() (pid term.Pid) {
-	// FIXME: make proper allocation, now it just stub
-	var id uint32 = 0
-	for k, _ := range n.channels {
-		if k.Id >= id {
-			id = k.Id + 1
-		}
-	}
-	pid.Node = term.Atom(n.FullName)
-	pid.Id = id
-	pid.Serial = 0 // FIXME
-	pid.Creation = byte(n.Creation)
-	return
-}
-
-func (n *Node) Connect(remote string) {
-
+	return n.procs.registeredNames()
 }
 
 func (n *Node) Publish(port int) (err error) {
 	nLog("Publish ENode at %d", port)
-	l, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	l, err := n.transport.Listen(n.listenAddr(port))
 	if err != nil {
 		return
 	}
 	n.Port = uint16(port)
-	aliveResp := make(chan uint16)
-	go epmdC(n, aliveResp)
-	creation := <-aliveResp
-	switch creation {
-	case 99:
-		return fmt.Errorf("Duplicate name '%s'", n.Name)
-	case 100:
-		return fmt.Errorf("Cannot connect to EPMD")
-	default:
-		n.Creation = creation
+	creation, err := n.registerWithEPMD()
+	if err != nil {
+		return err
 	}
+	n.Creation = creation
+	n.procs.creation = byte(creation)
 
 	go func() {
 		for {
@@ -179,21 +167,26 @@ func (n *Node) Publish(port int) (err error) {
 }
 
 func (currNode *Node) mLoop(c net.Conn) {
-
 	currNd := dist.NewNodeDesc(currNode.FullName, currNode.Cookie, false)
+	currNode.readLoop(c, currNd)
+}
 
+// readLoop pumps distribution messages off an already handshaken connection
+// until it errors out, dispatching each to handleTerms. It is shared by the
+// inbound (mLoop) and outbound (Connect) sides of the distribution protocol.
+func (currNode *Node) readLoop(c net.Conn, nd *dist.NodeDesc) {
 	for {
-		terms, err := currNd.ReadMessage(c)
+		terms, err := nd.ReadMessage(c)
 		if err != nil {
 			nLog("Enode error: %s", err.Error())
 			break
 		}
-		currNode.handleTerms(c, terms)
+		currNode.handleTerms(c, nd, terms)
 	}
 	c.Close()
 }
 
-func (currNode *Node) handleTerms(c net.Conn, terms []term.Term) {
+func (currNode *Node) handleTerms(c net.Conn, nd *dist.NodeDesc, terms []term.Term) {
 	nLog("Node terms: %#v", terms)
 
 	if len(terms) == 0 {
@@ -202,6 +195,7 @@ func (currNode *Node) handleTerms(c net.Conn, terms []term.Term) {
 	switch t := terms[0].(type) {
 	case term.Tuple:
 		if len(t) > 0 {
+			currNode.learnNeighbor(c, nd, t)
 			switch act := t.Element(1).(type) {
 			case term.Int:
 				switch act {
@@ -211,6 +205,13 @@ func (currNode *Node) handleTerms(c net.Conn, terms []term.Term) {
 					} else {
 						nLog("*** ERROR: bad REG_SEND: %#v", terms)
 					}
+				case SEND:
+					if len(terms) == 2 {
+						toPid, _ := t.Element(3).(term.Pid)
+						currNode.Send(toPid, terms[1])
+					} else {
+						nLog("*** ERROR: bad SEND: %#v", terms)
+					}
 				default:
 					nLog("Unhandled node message: %#v", t)
 				}
@@ -232,24 +233,97 @@ func (currNode *Node) RegSend(from, to term.Term, message term.Term) {
 }
 
 func (currNode *Node) Whereis(who term.Atom) (pid term.Pid) {
-	pid, _ = currNode.registered[who]
-	return
+	return currNode.procs.whereis(who)
 }
 
 func (currNode *Node) SendFrom(from term.Term, to term.Pid, message term.Term) {
 	nLog("SendFrom: %#v, %#v, %#v", from, to, message)
-	pcs := currNode.channels[to]
+	pcs, ok := currNode.procs.load(to)
+	if !ok {
+		nLog("SendFrom: no such process %#v", to)
+		return
+	}
 	pcs.inFrom <- term.Tuple{from, message}
 }
 
 func (currNode *Node) Send(to term.Pid, message term.Term) {
 	nLog("Send: %#v, %#v", to, message)
-	pcs := currNode.channels[to]
+	if to.Node != term.Atom(currNode.FullName) {
+		currNode.sendRemote(to, message)
+		return
+	}
+	pcs, ok := currNode.procs.load(to)
+	if !ok {
+		nLog("Send: no such process %#v", to)
+		return
+	}
 	pcs.in <- message
 }
 
+// learnNeighbor registers c as the route back to a peer node the first time
+// that peer's identity shows up in a control message we didn't dial
+// ourselves, so replies to an inbound (accepted) connection have somewhere
+// to go. The node name is learned from any control tuple whose second
+// element is a pid on another node, such as REG_SEND's "from" pid; SEND's
+// control tuple only names the destination, so it can't register a new
+// neighbor on its own.
+func (currNode *Node) learnNeighbor(c net.Conn, nd *dist.NodeDesc, t term.Tuple) {
+	fromPid, ok := t.Element(2).(term.Pid)
+	if !ok || fromPid.Node == term.Atom(currNode.FullName) {
+		return
+	}
+	if _, known := currNode.neighbors.load(fromPid.Node); known {
+		return
+	}
+	nc := nodeConn{conn: c, send: make(chan []term.Term, 100)}
+	currNode.neighbors.store(fromPid.Node, nc)
+	go currNode.neighborWriter(nd, nc)
+}
+
+// sendRemote delivers message to a pid living on a node we are connected to,
+// encoding it as the SEND control message handleTerms decodes.
+func (currNode *Node) sendRemote(to term.Pid, message term.Term) {
+	nc, ok := currNode.neighbors.load(to.Node)
+	if !ok {
+		nLog("Send: no connection to node %s, dropping message to %#v", to.Node, to)
+		return
+	}
+	ctl := term.Tuple{term.Int(SEND), term.Atom(""), to}
+	nc.send <- []term.Term{ctl, message}
+}
+
+// listenAddr is the address Publish listens on. Under pipeTransport there
+// are no real ports to bind, so nodes are addressed by full name instead.
+func (n *Node) listenAddr(port int) string {
+	if _, ok := n.transport.(pipeTransport); ok {
+		return n.FullName
+	}
+	return net.JoinHostPort("", strconv.Itoa(port))
+}
+
+// registerWithEPMD publishes this node's distribution port so other nodes
+// can find it: over the real EPMD protocol for the TCP/TLS transports, or
+// in the in-process pipeEPMD registry when running over a pipeTransport.
+func (n *Node) registerWithEPMD() (creation uint16, err error) {
+	if _, ok := n.transport.(pipeTransport); ok {
+		pipeEPMDRegister(n.FullName, n.Port)
+		return 1, nil
+	}
+
+	aliveResp := make(chan uint16)
+	go epmdC(n, aliveResp)
+	creation = <-aliveResp
+	switch creation {
+	case 99:
+		return 0, fmt.Errorf("Duplicate name '%s'", n.Name)
+	case 100:
+		return 0, fmt.Errorf("Cannot connect to EPMD")
+	}
+	return creation, nil
+}
+
 func epmdC(n *Node, resp chan uint16) {
-	conn, err := net.Dial("tcp", ":4369")
+	conn, err := n.transport.Dial("tcp", ":4369")
 	defer conn.Close()
 	if err != nil {
 		resp <- 100
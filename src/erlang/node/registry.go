@@ -0,0 +1,119 @@
+package node
+
+import (
+	"erlang/term"
+	"sync"
+	"sync/atomic"
+)
+
+// Pid ids/serials are limited to 28 and 13 bits respectively by the
+// distribution protocol (see erl_interface's make_pid).
+const (
+	maxPidId     = 1<<28 - 1
+	maxPidSerial = 1<<13 - 1
+)
+
+// processRegistry replaces the single channels/registered maps that used to
+// live directly on Node: pid allocation is a lock-free atomic counter, the
+// process table is a sync.Map so Send/SendFrom stay lock-free on the fast
+// path, and only the (far less hot) name table is guarded by a mutex.
+type processRegistry struct {
+	nextId   uint32 // atomic
+	serial   uint32 // atomic
+	fullName term.Atom
+	creation byte
+
+	procs sync.Map // term.Pid -> procChannels
+
+	namesMu  sync.RWMutex
+	names    map[term.Atom]term.Pid
+	namesRev map[term.Pid]term.Atom
+}
+
+func newProcessRegistry(fullName term.Atom, creation byte) *processRegistry {
+	return &processRegistry{
+		fullName: fullName,
+		creation: creation,
+		names:    make(map[term.Atom]term.Pid),
+		namesRev: make(map[term.Pid]term.Atom),
+	}
+}
+
+func (r *processRegistry) allocatePid() (pid term.Pid) {
+	id := atomic.AddUint32(&r.nextId, 1) - 1
+	if id > maxPidId {
+		// Wrapped: reset the id counter and bump Serial, per the
+		// distribution protocol's 28-bit id / 13-bit serial split.
+		atomic.StoreUint32(&r.nextId, 1)
+		id = 0
+		serial := atomic.AddUint32(&r.serial, 1)
+		if serial > maxPidSerial {
+			atomic.StoreUint32(&r.serial, 0)
+		}
+	}
+
+	pid.Node = r.fullName
+	pid.Id = id
+	pid.Serial = uint32(atomic.LoadUint32(&r.serial))
+	pid.Creation = r.creation
+	return
+}
+
+func (r *processRegistry) store(pid term.Pid, pcs procChannels) {
+	r.procs.Store(pid, pcs)
+}
+
+func (r *processRegistry) load(pid term.Pid) (pcs procChannels, ok bool) {
+	v, ok := r.procs.Load(pid)
+	if !ok {
+		return procChannels{}, false
+	}
+	return v.(procChannels), true
+}
+
+// remove drops pid from the process table and, if it was registered under a
+// name, from the name table too, so neither leaks stale entries once a
+// process has exited.
+func (r *processRegistry) remove(pid term.Pid) {
+	r.procs.Delete(pid)
+
+	r.namesMu.Lock()
+	if name, ok := r.namesRev[pid]; ok {
+		delete(r.names, name)
+		delete(r.namesRev, pid)
+	}
+	r.namesMu.Unlock()
+}
+
+func (r *processRegistry) register(name term.Atom, pid term.Pid) {
+	r.namesMu.Lock()
+	r.names[name] = pid
+	r.namesRev[pid] = name
+	r.namesMu.Unlock()
+}
+
+func (r *processRegistry) unregister(name term.Atom) {
+	r.namesMu.Lock()
+	if pid, ok := r.names[name]; ok {
+		delete(r.namesRev, pid)
+	}
+	delete(r.names, name)
+	r.namesMu.Unlock()
+}
+
+func (r *processRegistry) whereis(name term.Atom) (pid term.Pid) {
+	r.namesMu.RLock()
+	pid = r.names[name]
+	r.namesMu.RUnlock()
+	return
+}
+
+func (r *processRegistry) registeredNames() (names []term.Atom) {
+	r.namesMu.RLock()
+	defer r.namesMu.RUnlock()
+	names = make([]term.Atom, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	return
+}
@@ -0,0 +1,60 @@
+package node
+
+import (
+	"erlang/term"
+	"sync"
+	"testing"
+)
+
+func TestAllocatePidIsUniqueUnderConcurrentSpawn(t *testing.T) {
+	r := newProcessRegistry(term.Atom("test@localhost"), 0)
+
+	const n = 200
+	seen := make(chan term.Pid, n)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			seen <- r.allocatePid()
+		}()
+	}
+	wg.Wait()
+	close(seen)
+
+	ids := make(map[term.Pid]bool)
+	for pid := range seen {
+		if ids[pid] {
+			t.Fatalf("allocatePid returned duplicate pid %#v under concurrent spawn", pid)
+		}
+		ids[pid] = true
+	}
+	if len(ids) != n {
+		t.Fatalf("expected %d unique pids, got %d", n, len(ids))
+	}
+}
+
+func TestRegistryRemoveCleansUpRegisteredName(t *testing.T) {
+	r := newProcessRegistry(term.Atom("test@localhost"), 0)
+	pid := r.allocatePid()
+	r.store(pid, procChannels{})
+	r.register(term.Atom("net_kernel"), pid)
+
+	if got := r.whereis(term.Atom("net_kernel")); got != pid {
+		t.Fatalf("whereis: expected %#v, got %#v", pid, got)
+	}
+
+	r.remove(pid)
+
+	if _, ok := r.load(pid); ok {
+		t.Fatalf("remove: pid entry still present after remove")
+	}
+	if got := r.whereis(term.Atom("net_kernel")); got != (term.Pid{}) {
+		t.Fatalf("remove: name 'net_kernel' still resolves to %#v after its process exited", got)
+	}
+	for _, name := range r.registeredNames() {
+		if name == term.Atom("net_kernel") {
+			t.Fatalf("remove: 'net_kernel' still listed in registeredNames()")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package node
+
+import (
+	"erlang/term"
+	"sync"
+)
+
+// rpcTable guards the module/function -> Go func registry: RegisterRPC is
+// typically called while the rex process (spawned during Publish) is
+// already live and able to service calls concurrently.
+type rpcTable struct {
+	mu    sync.RWMutex
+	funcs map[string]map[string]func(args term.List) term.Term
+}
+
+func newRPCTable() *rpcTable {
+	return &rpcTable{funcs: make(map[string]map[string]func(args term.List) term.Term)}
+}
+
+func (t *rpcTable) register(module, function string, fn func(args term.List) term.Term) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	funs, ok := t.funcs[module]
+	if !ok {
+		funs = make(map[string]func(args term.List) term.Term)
+		t.funcs[module] = funs
+	}
+	funs[function] = fn
+}
+
+func (t *rpcTable) lookup(module, function string) func(args term.List) term.Term {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.funcs[module][function]
+}
+
+// RegisterRPC makes fn callable as rpc:call(ThisNode, module, function, Args)
+// from Erlang, dispatched through the built-in rex process.
+func (n *Node) RegisterRPC(module, function string, fn func(args term.List) term.Term) {
+	n.rpc.register(module, function, fn)
+}
+
+func (n *Node) lookupRPC(module, function string) func(args term.List) term.Term {
+	return n.rpc.lookup(module, function)
+}
+
+// rex is the built-in gen_server-style process backing Erlang's rpc:call/4:
+// it receives {call, Module, Function, Args, GroupLeader} via gen_server
+// call and dispatches to a function registered with Node.RegisterRPC.
+type rex struct {
+	node *Node
+}
+
+func (r *rex) Behaviour() (behaviour Behaviour, options map[string]interface{}) {
+	return new(GenServer), nil
+}
+
+func (r *rex) Init(args ...interface{}) {
+	if len(args) > 0 {
+		r.node, _ = args[0].(*Node)
+	}
+}
+
+func (r *rex) HandleCall(message *term.Term, from *term.Tuple) (reply *term.Term) {
+	t, ok := (*message).(term.Tuple)
+	if !ok || len(t) == 0 {
+		return nil
+	}
+	if tag, _ := t.Element(1).(term.Atom); tag != term.Atom("call") {
+		return nil
+	}
+
+	module, _ := t.Element(2).(term.Atom)
+	function, _ := t.Element(3).(term.Atom)
+	callArgs, _ := t.Element(4).(term.List)
+
+	var result term.Term
+	if fn := r.node.lookupRPC(string(module), string(function)); fn != nil {
+		result = fn(callArgs)
+	} else {
+		mfa := term.Tuple{module, function, callArgs, term.List{}}
+		result = term.Tuple{
+			term.Atom("badrpc"),
+			term.Tuple{term.Atom("EXIT"), term.Tuple{term.Atom("undef"), term.List{mfa}}},
+		}
+	}
+	return &result
+}
+
+func (r *rex) HandleCast(message *term.Term) (result string) {
+	return "noreply"
+}
+
+func (r *rex) HandleInfo(message *term.Term) (result string) {
+	return "noreply"
+}
+
+func (r *rex) Terminate(reason string) {
+}
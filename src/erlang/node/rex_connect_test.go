@@ -0,0 +1,66 @@
+package node
+
+import (
+	"erlang/term"
+	"testing"
+	"time"
+)
+
+// TestRexDispatchesRPCAcrossConnectedNodes exercises rex the way a real
+// rpc:call(Node, Mod, Fun, Args) from Erlang would: the caller dials in via
+// Connect (so the callee is the accept side of the connection, exactly the
+// path chunk0-3's neighbor-on-accept fix was required for) and the callee's
+// rex process sends its reply back over that same connection. rex_test.go's
+// callRex only exercises rex with a same-node fake caller pid, so it can't
+// catch a dropped reply on the cross-node path the way this test can.
+func TestRexDispatchesRPCAcrossConnectedNodes(t *testing.T) {
+	transport := NewPipeTransport()
+
+	callee := NewNode("callee1@localhost", "cookie", WithTransport(transport))
+	callee.RegisterRPC("mymod", "myfun", func(args term.List) term.Term {
+		return term.Tuple{term.Atom("ok"), args}
+	})
+	if err := callee.Publish(0); err != nil {
+		t.Fatalf("callee Publish: %v", err)
+	}
+
+	caller := NewNode("caller1@localhost", "cookie", WithTransport(transport))
+	if err := caller.Connect("callee1@localhost"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	replies := make(chan term.Term, 1)
+	callerPid := caller.procs.allocatePid()
+	caller.procs.store(callerPid, procChannels{in: replies})
+
+	nc, ok := caller.neighbors.load(term.Atom("callee1@localhost"))
+	if !ok {
+		t.Fatal("caller has no neighbor entry for callee1@localhost after Connect")
+	}
+
+	ref := term.Atom("ref-rpc")
+	msg := term.Tuple{
+		term.Atom("call"), term.Atom("mymod"), term.Atom("myfun"),
+		term.List{term.Int(1), term.Int(2)}, term.Atom("group_leader"),
+	}
+	ctl := term.Tuple{term.Int(REG_SEND), callerPid, term.Atom(""), term.Atom("rex")}
+	gcall := term.Tuple{term.Atom("$gen_call"), term.Tuple{callerPid, ref}, msg}
+	nc.send <- []term.Term{ctl, gcall}
+
+	select {
+	case got := <-replies:
+		reply, ok := got.(term.Tuple)
+		if !ok || len(reply) != 2 {
+			t.Fatalf("expected a 2-tuple reply, got %#v", got)
+		}
+		if reply.Element(1) != term.Term(ref) {
+			t.Fatalf("expected reply ref %#v, got %#v", ref, reply.Element(1))
+		}
+		result, ok := reply.Element(2).(term.Tuple)
+		if !ok || result.Element(1) != term.Term(term.Atom("ok")) {
+			t.Fatalf("expected {ok, Args}, got %#v", reply.Element(2))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for rex's reply to route back across the connection")
+	}
+}
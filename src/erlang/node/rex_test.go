@@ -0,0 +1,70 @@
+package node
+
+import (
+	"erlang/term"
+	"testing"
+	"time"
+)
+
+func callRex(t *testing.T, node *Node, module, function string, callArgs term.List) term.Term {
+	t.Helper()
+
+	rexPid := node.Whereis(term.Atom("rex"))
+	caller := node.procs.allocatePid()
+	replies := make(chan term.Term, 1)
+	node.procs.store(caller, procChannels{in: replies})
+
+	ref := term.Atom("ref")
+	msg := term.Tuple{term.Atom("call"), term.Atom(module), term.Atom(function), callArgs, term.Atom("group_leader")}
+	node.Send(rexPid, term.Tuple{term.Atom("$gen_call"), term.Tuple{caller, ref}, msg})
+
+	select {
+	case got := <-replies:
+		reply, ok := got.(term.Tuple)
+		if !ok || len(reply) != 2 {
+			t.Fatalf("expected a 2-tuple reply, got %#v", got)
+		}
+		return reply.Element(2)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for rex reply")
+		return nil
+	}
+}
+
+func TestRexDispatchesRegisteredRPC(t *testing.T) {
+	node := NewNode("rex1@localhost", "cookie")
+	node.RegisterRPC("mymod", "myfun", func(args term.List) term.Term {
+		return term.Tuple{term.Atom("ok"), args}
+	})
+	// rex is normally spawned and registered by prepareProcesses during
+	// Publish; do that directly here since this test skips Publish.
+	rexPid := node.Spawn(new(rex), node)
+	node.Register(term.Atom("rex"), rexPid)
+
+	callArgs := term.List{term.Int(1), term.Int(2)}
+	result := callRex(t, node, "mymod", "myfun", callArgs)
+
+	reply, ok := result.(term.Tuple)
+	if !ok || len(reply) != 2 {
+		t.Fatalf("expected {ok, Args}, got %#v", result)
+	}
+	if reply.Element(1) != term.Term(term.Atom("ok")) {
+		t.Fatalf("expected 'ok' tag, got %#v", reply.Element(1))
+	}
+}
+
+func TestRexBadRPCForUnregisteredFunction(t *testing.T) {
+	node := NewNode("rex2@localhost", "cookie")
+	rexPid := node.Spawn(new(rex), node)
+	node.Register(term.Atom("rex"), rexPid)
+
+	result := callRex(t, node, "nosuchmod", "nosuchfun", term.List{})
+
+	reply, ok := result.(term.Tuple)
+	if !ok || len(reply) != 2 {
+		t.Fatalf("expected {badrpc, ...}, got %#v", result)
+	}
+	if reply.Element(1) != term.Term(term.Atom("badrpc")) {
+		t.Fatalf("expected 'badrpc' tag for unregistered function, got %#v", reply.Element(1))
+	}
+}
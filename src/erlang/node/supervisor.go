@@ -0,0 +1,241 @@
+package node
+
+import (
+	"erlang/term"
+	"time"
+)
+
+// Restart strategies, named after the OTP supervisor strategies they mirror.
+const (
+	StrategyOneForOne       = "one_for_one"
+	StrategyOneForAll       = "one_for_all"
+	StrategyRestForOne      = "rest_for_one"
+	StrategySimpleOneForOne = "simple_one_for_one"
+)
+
+// ChildSpec describes one supervised child, mirroring the Erlang
+// {Id, StartFunc, Restart, Shutdown, Type, Modules} child spec tuple.
+type ChildSpec struct {
+	Id       string
+	Process  Process
+	Args     []interface{}
+	// Restart is "permanent", "transient" or "temporary". The Supervisor does
+	// not consult it: a child is restarted when it exits with any reason
+	// other than normal or shutdown, and is not restarted otherwise.
+	Restart  string
+	Shutdown time.Duration
+	Type     string // "worker" or "supervisor"
+}
+
+// SupervisorSpec is returned from a SupervisorBehaviour's Init, mirroring
+// the {RestartStrategy, MaxRestarts, MaxSeconds} tuple Erlang supervisors
+// return alongside their child specs.
+type SupervisorSpec struct {
+	Children    []ChildSpec
+	Strategy    string
+	MaxRestarts int
+	MaxSeconds  int
+}
+
+// SupervisorBehaviour is implemented by a Process that wants to be driven
+// by the Supervisor Behaviour.
+type SupervisorBehaviour interface {
+	Init(args ...interface{}) SupervisorSpec
+}
+
+// Supervisor is a Behaviour implementing an OTP-style supervisor: it spawns
+// the children declared by the SupervisorBehaviour's Init, monitors them via
+// their procChannels exit channel, and restarts them according to the
+// declared strategy and restart intensity.
+type Supervisor struct {
+}
+
+type supervisorChild struct {
+	spec   ChildSpec
+	pid    term.Pid
+	exited chan term.Tuple // fed once by this child's monitor goroutine; stopChild waits on it directly
+}
+
+func (s *Supervisor) ProcessLoop(pid term.Pid, pcs procChannels, pd Process, args ...interface{}) {
+	sb, ok := pd.(SupervisorBehaviour)
+	if !ok {
+		nLog("Supervisor: %#v does not implement SupervisorBehaviour", pd)
+		return
+	}
+
+	var node *Node
+	if len(args) > 0 {
+		node, _ = args[0].(*Node)
+	}
+	if node == nil {
+		nLog("Supervisor: no Node given in args, cannot spawn children")
+		return
+	}
+
+	spec := sb.Init(args...)
+	down := make(chan term.Tuple, len(spec.Children))
+	children := make([]*supervisorChild, len(spec.Children))
+	for i, cs := range spec.Children {
+		children[i] = &supervisorChild{spec: cs}
+	}
+	s.startChildren(node, children, down)
+
+	restarts := make([]time.Time, 0)
+
+	for {
+		select {
+		case exit := <-down:
+			deadPid, _ := exit.Element(1).(term.Pid)
+			reason, _ := exit.Element(2).(term.Atom)
+			if reason == "normal" || reason == "shutdown" {
+				continue
+			}
+
+			restarts = append(restarts, time.Now())
+			restarts = evictOldRestarts(restarts, spec.MaxSeconds)
+			if len(restarts) > spec.MaxRestarts {
+				nLog("Supervisor %#v: restart intensity exceeded, shutting down", pid)
+				s.stopChildren(node, children)
+				return
+			}
+
+			idx := -1
+			for i, c := range children {
+				if c.pid == deadPid {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				continue
+			}
+
+			switch spec.Strategy {
+			case StrategyOneForAll:
+				s.stopChildren(node, children)
+				s.startChildren(node, children, down)
+			case StrategyRestForOne:
+				s.stopChildrenFrom(node, children, idx)
+				s.startChildrenFrom(node, children, idx, down)
+			default: // one_for_one, simple_one_for_one
+				s.startChild(node, children[idx], down)
+			}
+
+		case req := <-pcs.in:
+			if spec.Strategy == StrategySimpleOneForOne {
+				if childArgs, ok := decodeStartChild(req); ok && len(spec.Children) > 0 {
+					template := spec.Children[0]
+					template.Args = childArgs
+					c := &supervisorChild{spec: template}
+					s.startChild(node, c, down)
+					children = append(children, c)
+				}
+			}
+
+		case ctl := <-pcs.ctl:
+			if ctl == term.Term(term.Atom("$terminate")) {
+				s.stopChildren(node, children)
+				return
+			}
+		}
+	}
+}
+
+// decodeStartChild recognizes the {'$start_child', Args} message Node.StartChild
+// sends to a simple_one_for_one supervisor to spawn a new instance of its
+// child template.
+func decodeStartChild(req term.Term) (args []interface{}, ok bool) {
+	t, ok := req.(term.Tuple)
+	if !ok || len(t) == 0 {
+		return nil, false
+	}
+	tag, ok := t.Element(1).(term.Atom)
+	if !ok || tag != term.Atom("$start_child") {
+		return nil, false
+	}
+	childArgs, _ := t.Element(2).(term.List)
+	args = make([]interface{}, len(childArgs))
+	for i, a := range childArgs {
+		args[i] = a
+	}
+	return args, true
+}
+
+func (s *Supervisor) startChildren(node *Node, children []*supervisorChild, down chan term.Tuple) {
+	for _, c := range children {
+		s.startChild(node, c, down)
+	}
+}
+
+func (s *Supervisor) startChildrenFrom(node *Node, children []*supervisorChild, from int, down chan term.Tuple) {
+	for _, c := range children[from:] {
+		s.startChild(node, c, down)
+	}
+}
+
+func (s *Supervisor) startChild(node *Node, c *supervisorChild, down chan term.Tuple) {
+	c.pid = node.Spawn(c.spec.Process, c.spec.Args...)
+	c.exited = make(chan term.Tuple, 1)
+	pcs, _ := node.procs.load(c.pid)
+	go func(exitCh chan term.Tuple, exited chan term.Tuple) {
+		if reason, ok := <-exitCh; ok {
+			exited <- reason
+			down <- reason
+		}
+	}(pcs.exit, c.exited)
+}
+
+func (s *Supervisor) stopChildren(node *Node, children []*supervisorChild) {
+	for i := len(children) - 1; i >= 0; i-- {
+		s.stopChild(node, children[i])
+	}
+}
+
+func (s *Supervisor) stopChildrenFrom(node *Node, children []*supervisorChild, from int) {
+	for i := len(children) - 1; i >= from; i-- {
+		s.stopChild(node, children[i])
+	}
+}
+
+// stopChild asks a still-running child to terminate and waits up to its
+// Shutdown timeout for confirmation. It waits on c.exited rather than the
+// process's own pcs.exit channel, because that channel is also drained
+// (exactly once) by the monitor goroutine startChild spawned; racing a
+// second reader against the monitor would starve stopChild and make it
+// always fall through to the full timeout instead of returning as soon as
+// the child actually exits.
+func (s *Supervisor) stopChild(node *Node, c *supervisorChild) {
+	pcs, ok := node.procs.load(c.pid)
+	if !ok {
+		return
+	}
+	pcs.ctl <- term.Term(term.Atom("$terminate"))
+
+	select {
+	case <-c.exited:
+	case <-time.After(c.spec.Shutdown):
+	}
+}
+
+// StartChild asks a simple_one_for_one supervisor to spawn a new instance of
+// its child template with args, mirroring Erlang's
+// supervisor:start_child(Supervisor, Args).
+func (n *Node) StartChild(supervisor term.Pid, args ...term.Term) {
+	n.Send(supervisor, term.Tuple{term.Atom("$start_child"), term.List(args)})
+}
+
+// evictOldRestarts drops restart timestamps older than maxSeconds, keeping
+// only the ones relevant to the current restart-intensity window.
+func evictOldRestarts(restarts []time.Time, maxSeconds int) []time.Time {
+	if maxSeconds <= 0 {
+		return restarts
+	}
+	cutoff := time.Now().Add(-time.Duration(maxSeconds) * time.Second)
+	kept := restarts[:0]
+	for _, t := range restarts {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
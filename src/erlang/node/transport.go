@@ -0,0 +1,161 @@
+package node
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Transport abstracts the net.Listen/net.Dial calls Publish, Connect and
+// epmdC make, so a Node can be wired up over plain TCP, TLS, or an
+// in-process net.Pipe for tests, via a NodeOption.
+type Transport interface {
+	Listen(addr string) (net.Listener, error)
+	Dial(network, addr string) (net.Conn, error)
+}
+
+// NodeOption configures a Node at construction time, passed to NewNode.
+type NodeOption func(*Node)
+
+// WithTransport selects the Transport a Node uses to Publish and Connect.
+// The default is plain TCP.
+func WithTransport(t Transport) NodeOption {
+	return func(n *Node) {
+		n.transport = t
+	}
+}
+
+// tcpTransport is the default Transport: plain, unencrypted TCP.
+type tcpTransport struct{}
+
+func (tcpTransport) Listen(addr string) (net.Listener, error) {
+	return net.Listen("tcp", addr)
+}
+
+func (tcpTransport) Dial(network, addr string) (net.Conn, error) {
+	return net.Dial(network, addr)
+}
+
+// tlsTransport runs the distribution protocol over TLS, for clusters that
+// need encrypted inter-node traffic.
+type tlsTransport struct {
+	config *tls.Config
+}
+
+// NewTLSTransport returns a Transport that listens and dials over TLS using
+// config.
+func NewTLSTransport(config *tls.Config) Transport {
+	return tlsTransport{config: config}
+}
+
+func (t tlsTransport) Listen(addr string) (net.Listener, error) {
+	return tls.Listen("tcp", addr, t.config)
+}
+
+func (t tlsTransport) Dial(network, addr string) (net.Conn, error) {
+	return tls.Dial(network, addr, t.config)
+}
+
+// pipeTransport wires Nodes together with net.Pipe instead of real sockets,
+// keyed by the address passed to Listen/Dial. It lets several Node
+// instances in the same process Connect/RegSend/supervise each other in
+// tests without opening sockets: Node.Publish and Node.Connect recognize
+// this transport and resolve distribution ports through the in-process
+// pipeEPMD registry below instead of a real running EPMD.
+type pipeTransport struct{}
+
+// NewPipeTransport returns an in-process Transport backed by net.Pipe.
+func NewPipeTransport() Transport {
+	return pipeTransport{}
+}
+
+var pipeRegistry = struct {
+	sync.Mutex
+	listeners map[string]*pipeListener
+}{listeners: make(map[string]*pipeListener)}
+
+type pipeListener struct {
+	addr   string
+	conns  chan net.Conn
+	closed chan struct{}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, fmt.Errorf("pipe listener '%s' closed", l.addr)
+	}
+}
+
+func (l *pipeListener) Close() error {
+	pipeRegistry.Lock()
+	delete(pipeRegistry.listeners, l.addr)
+	pipeRegistry.Unlock()
+	close(l.closed)
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.addr)
+}
+
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+func (pipeTransport) Listen(addr string) (net.Listener, error) {
+	pipeRegistry.Lock()
+	defer pipeRegistry.Unlock()
+	if _, ok := pipeRegistry.listeners[addr]; ok {
+		return nil, fmt.Errorf("pipe address '%s' already in use", addr)
+	}
+	l := &pipeListener{
+		addr:   addr,
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+	pipeRegistry.listeners[addr] = l
+	return l, nil
+}
+
+// pipeEPMD is a minimal in-process stand-in for a running EPMD, used only
+// by pipeTransport: it maps a node's full name to the distribution port it
+// published, so Connect can resolve it without dialing a real EPMD.
+var pipeEPMD = struct {
+	sync.Mutex
+	ports map[string]uint16
+}{ports: make(map[string]uint16)}
+
+func pipeEPMDRegister(fullName string, port uint16) {
+	pipeEPMD.Lock()
+	pipeEPMD.ports[fullName] = port
+	pipeEPMD.Unlock()
+}
+
+func pipeEPMDLookup(fullName string) (port uint16, ok bool) {
+	pipeEPMD.Lock()
+	port, ok = pipeEPMD.ports[fullName]
+	pipeEPMD.Unlock()
+	return
+}
+
+func (pipeTransport) Dial(network, addr string) (net.Conn, error) {
+	pipeRegistry.Lock()
+	l, ok := pipeRegistry.listeners[addr]
+	pipeRegistry.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no pipe listener at '%s'", addr)
+	}
+
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.closed:
+		return nil, fmt.Errorf("pipe listener '%s' closed", l.addr)
+	}
+}
@@ -0,0 +1,81 @@
+package node
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPipeTransportListenAndDialRoundTrip(t *testing.T) {
+	pt := NewPipeTransport()
+	l, err := pt.Listen("node1@localhost")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer l.Close()
+
+	accepted := make(chan io.ReadWriteCloser, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := pt.Dial("tcp", "node1@localhost")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer client.Close()
+
+	var server io.ReadWriteCloser
+	select {
+	case server = <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("Listen side never accepted the Dial")
+	}
+	defer server.Close()
+
+	go client.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(server, buf); err != nil {
+		t.Fatalf("server read: %v", err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("expected 'ping', got %q", buf)
+	}
+}
+
+func TestPipeTransportDialWithNoListenerFails(t *testing.T) {
+	pt := NewPipeTransport()
+	if _, err := pt.Dial("tcp", "nobody@nowhere"); err == nil {
+		t.Fatal("expected Dial to fail with no matching Listen")
+	}
+}
+
+func TestPipeEPMDRegisterAndLookup(t *testing.T) {
+	pipeEPMDRegister("epmdtest@localhost", 5555)
+	port, ok := pipeEPMDLookup("epmdtest@localhost")
+	if !ok || port != 5555 {
+		t.Fatalf("expected (5555, true), got (%d, %v)", port, ok)
+	}
+
+	if _, ok := pipeEPMDLookup("neverpublished@localhost"); ok {
+		t.Fatal("expected lookup of an unpublished node to fail")
+	}
+}
+
+// This guards the bug where Publish's listenAddr and Connect's dialAddr
+// disagreed under pipeTransport (one keyed by host:port, the other by full
+// node name), which silently made Connect unable to find the listener.
+func TestListenAddrAndDialAddrAgreeUnderPipeTransport(t *testing.T) {
+	node := NewNode("pipe1@localhost", "cookie", WithTransport(NewPipeTransport()))
+	if got, want := node.listenAddr(9999), "pipe1@localhost"; got != want {
+		t.Fatalf("listenAddr: got %q, want %q", got, want)
+	}
+	if got, want := node.dialAddr("pipe1@localhost", 9999), "pipe1@localhost"; got != want {
+		t.Fatalf("dialAddr: got %q, want %q", got, want)
+	}
+}